@@ -0,0 +1,98 @@
+package dbr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDriver backs a *sql.DB with a canned, in-memory result set instead of
+// a real database, so Session's reflection-based scanning and statement
+// caching can be exercised without a DB dependency. Every Query/Exec
+// returns the same canned rows/result regardless of the SQL text; tests
+// that care about Prepare/Close traffic read prepareCount/closeCount.
+type fakeDriver struct {
+	columns []string
+	rows    [][]driver.Value
+
+	prepareCount int32
+	closeCount   int32
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d: d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt32(&c.d.prepareCount, 1)
+	return &fakeStmt{d: c.d}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakeDriver: transactions not supported")
+}
+
+type fakeStmt struct {
+	d *fakeDriver
+}
+
+func (s *fakeStmt) Close() error {
+	atomic.AddInt32(&s.d.closeCount, 1)
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{columns: s.d.columns, rows: s.d.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+var fakeDriverCounter int32
+
+// newFakeSession registers a fresh fakeDriver under a unique name and
+// returns a Session wired up to query it, plus the driver itself so tests
+// can inspect prepareCount/closeCount.
+func newFakeSession(t *testing.T, columns []string, rows [][]driver.Value) (*Session, *fakeDriver) {
+	t.Helper()
+	name := fmt.Sprintf("dbr-fake-%d", atomic.AddInt32(&fakeDriverCounter, 1))
+	d := &fakeDriver{columns: columns, rows: rows}
+	sql.Register(name, d)
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open: %s", err)
+	}
+	return &Session{cxn: &Connection{Db: db}}, d
+}
@@ -0,0 +1,129 @@
+package dbr
+
+import (
+	"database/sql"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// fieldMap maps a column name, as read from a struct field's "db" tag (or
+// the field's own name when untagged), to the reflect.Value.FieldByIndex
+// path needed to reach it. Embedded (anonymous) struct fields are flattened
+// straight into the parent's map; named nested struct fields are reachable
+// under a dotted "parent.child" name joined from each level's "db" tag (or
+// field name), e.g. "address.city".
+type fieldMap map[string][]int
+
+var fieldMapCache sync.Map // map[reflect.Type]fieldMap
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldMapFor returns the fieldMap describing t's columns, building and
+// caching it on first use so repeated scans of the same struct type don't
+// re-walk its fields with reflection.
+func fieldMapFor(t reflect.Type) fieldMap {
+	if cached, ok := fieldMapCache.Load(t); ok {
+		return cached.(fieldMap)
+	}
+
+	fm := fieldMap{}
+	addFieldsOf(fm, t, nil, "")
+
+	actual, _ := fieldMapCache.LoadOrStore(t, fm)
+	return actual.(fieldMap)
+}
+
+// addFieldsOf walks t's fields into fm. index is the FieldByIndex path to t
+// itself (nil at the top level); prefix is the dotted "db" name prefix
+// contributed by any named (non-embedded) nested struct we've descended
+// into.
+func addFieldsOf(fm fieldMap, t reflect.Type, index []int, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // unexported
+		}
+
+		name := field.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && fieldType.Kind() == reflect.Struct {
+			// Embedded struct: its fields are promoted straight into ours.
+			addFieldsOf(fm, fieldType, fieldIndex, prefix)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType {
+			// Named nested struct: its fields hang off a dotted
+			// "parent.child" name, e.g. `Org Org` -> "Org.Name".
+			addFieldsOf(fm, fieldType, fieldIndex, name)
+			continue
+		}
+
+		fm[name] = fieldIndex
+	}
+}
+
+// holderFor returns one scan address per column in rows, pointing into
+// val's fields (walking embedded/nested structs via recordType's fieldMap)
+// so that rows.Scan can populate them directly. Pointer fields along the
+// path to a column are allocated lazily, only when a row actually needs
+// them. Columns the struct has no field for are scanned into a throwaway
+// value and dropped.
+func (sess *Session) holderFor(recordType reflect.Type, val reflect.Value, rows *sql.Rows) ([]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fm := fieldMapFor(recordType)
+
+	var ignored sql.RawBytes
+	holder := make([]interface{}, len(columns))
+	for i, column := range columns {
+		index, ok := fm[column]
+		if !ok {
+			holder[i] = &ignored
+			continue
+		}
+
+		holder[i] = fieldByIndexAlloc(val, index).Addr().Interface()
+	}
+
+	return holder, nil
+}
+
+// fieldByIndexAlloc is reflect.Value.FieldByIndex, except that nil pointers
+// to structs along the path are allocated as it descends instead of
+// panicking.
+func fieldByIndexAlloc(val reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(i)
+	}
+	return val
+}
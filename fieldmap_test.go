@@ -0,0 +1,74 @@
+package dbr
+
+import (
+	"reflect"
+	"testing"
+)
+
+type fmBase struct {
+	ID int `db:"id"`
+}
+
+type fmAddress struct {
+	City string `db:"city"`
+}
+
+type fmUser struct {
+	fmBase
+	Name       string     `db:"name"`
+	Address    *fmAddress `db:"address"`
+	Hidden     string     `db:"-"`
+	unexported string
+}
+
+func TestFieldMapForPromotesEmbeddedFields(t *testing.T) {
+	fm := fieldMapFor(reflect.TypeOf(fmUser{}))
+
+	index, ok := fm["id"]
+	if !ok {
+		t.Fatalf("expected embedded fmBase.ID to be promoted as \"id\", got %v", fm)
+	}
+	if len(index) != 2 {
+		t.Fatalf("expected a 2-deep index path into the embedded struct, got %v", index)
+	}
+}
+
+func TestFieldMapForDottedNestedStruct(t *testing.T) {
+	fm := fieldMapFor(reflect.TypeOf(fmUser{}))
+
+	if _, ok := fm["address.city"]; !ok {
+		t.Fatalf("expected nested *fmAddress.City to be reachable as \"address.city\", got %v", fm)
+	}
+}
+
+func TestFieldMapForSkipsDashTagAndUnexported(t *testing.T) {
+	fm := fieldMapFor(reflect.TypeOf(fmUser{}))
+
+	if _, ok := fm["Hidden"]; ok {
+		t.Fatalf(`db:"-"` + ` field should not appear in the field map`)
+	}
+	if _, ok := fm["unexported"]; ok {
+		t.Fatalf("unexported field should not appear in the field map")
+	}
+}
+
+func TestFieldByIndexAllocLazilyAllocatesNilPointers(t *testing.T) {
+	fm := fieldMapFor(reflect.TypeOf(fmUser{}))
+	index := fm["address.city"]
+
+	var user fmUser
+	val := reflect.ValueOf(&user).Elem()
+
+	if user.Address != nil {
+		t.Fatalf("expected Address to start nil")
+	}
+
+	fieldByIndexAlloc(val, index).SetString("Springfield")
+
+	if user.Address == nil {
+		t.Fatalf("expected fieldByIndexAlloc to allocate the nil Address pointer")
+	}
+	if user.Address.City != "Springfield" {
+		t.Fatalf("expected Address.City to be set, got %q", user.Address.City)
+	}
+}
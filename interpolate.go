@@ -0,0 +1,180 @@
+package dbr
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmptySliceMode controls how Interpolate renders an empty slice/array
+// param. Configure it per session with Session.SetEmptySliceMode; the
+// package-level Interpolate function always uses the default,
+// EmptySliceNull.
+type EmptySliceMode int
+
+const (
+	// EmptySliceNull (the default) expands an empty slice param to a bare
+	// "NULL", so "IN (?)" becomes "IN (NULL)" and reliably matches no
+	// rows instead of producing invalid SQL like "IN ()".
+	EmptySliceNull EmptySliceMode = iota
+	// EmptySliceError aborts interpolation instead of substituting NULL,
+	// returning ErrEmptySliceParam with no usable SQL, for callers that
+	// would rather treat an empty slice param as a programming error.
+	EmptySliceError
+)
+
+// ErrEmptySliceParam is returned when a session configured with
+// EmptySliceError (see Session.SetEmptySliceMode) is given an empty
+// slice/array param to interpolate.
+var ErrEmptySliceParam = errors.New("dbr: Interpolate: empty slice param")
+
+var emptySliceModes sync.Map // map[*Session]EmptySliceMode
+
+// SetEmptySliceMode configures how this session's SelectAll, SelectOne,
+// and SelectIter calls render an empty slice/array param when
+// interpolating a query; see EmptySliceMode. Sessions default to
+// EmptySliceNull.
+func (sess *Session) SetEmptySliceMode(mode EmptySliceMode) {
+	emptySliceModes.Store(sess, mode)
+}
+
+func (sess *Session) emptySliceMode() EmptySliceMode {
+	if mode, ok := emptySliceModes.Load(sess); ok {
+		return mode.(EmptySliceMode)
+	}
+	return EmptySliceNull
+}
+
+// interpolate is Interpolate, but honors sess's configured EmptySliceMode.
+func (sess *Session) interpolate(sql string, params []interface{}) (string, error) {
+	return interpolateMode(sql, params, sess.emptySliceMode())
+}
+
+// Interpolate expands "?" placeholders in sql with literal, driver-escaped
+// values from params, returning a complete, self-contained SQL string with
+// no params left to bind. A param that is a slice or array (other than
+// []byte, which is left alone as an opaque blob) expands into one
+// comma-joined element per "?", so the placeholder is expected to already
+// sit inside its own parens, e.g. "IN (?)" with params []int64{1, 2, 3}
+// becomes "IN (1, 2, 3)" -- the same trick sqlx.In performs. Interpolate
+// does not add parens itself: a bare "?" (no surrounding parens) given a
+// slice param produces invalid SQL, by design, since the caller controls
+// where the parens belong. An empty slice is handled per EmptySliceNull,
+// the default; to configure this per session, see Session.SetEmptySliceMode.
+func Interpolate(sql string, params []interface{}) (string, error) {
+	return interpolateMode(sql, params, EmptySliceNull)
+}
+
+func interpolateMode(sql string, params []interface{}, mode EmptySliceMode) (string, error) {
+	var buf bytes.Buffer
+
+	paramIndex := 0
+	inString := false
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		if inString {
+			buf.WriteByte(c)
+			if c == '\'' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '\'':
+			inString = true
+			buf.WriteByte(c)
+		case '?':
+			if paramIndex >= len(params) {
+				return "", fmt.Errorf("dbr: Interpolate: not enough params for sql %q", sql)
+			}
+			literal, err := paramLiteral(params[paramIndex], mode)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(literal)
+			paramIndex++
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	if paramIndex != len(params) {
+		return "", fmt.Errorf("dbr: Interpolate: too many params for sql %q", sql)
+	}
+
+	return buf.String(), nil
+}
+
+// paramLiteral renders a single param as SQL. Slices and arrays (other than
+// []byte) expand to a bare, comma-joined list of their own literals -- the
+// caller's "(?)" is the only parenthesization, so the result must not add
+// its own or "IN (?)" would parse as a row-value constructor instead of a
+// scalar list; anything else is rendered as one scalar literal.
+func paramLiteral(param interface{}, mode EmptySliceMode) (string, error) {
+	if valuer, ok := param.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		return scalarLiteral(v)
+	}
+
+	val := reflect.ValueOf(param)
+	if val.IsValid() && (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+		if val.Len() == 0 {
+			if mode == EmptySliceError {
+				return "", ErrEmptySliceParam
+			}
+			return "NULL", nil
+		}
+
+		elems := make([]string, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			literal, err := paramLiteral(val.Index(i).Interface(), mode)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = literal
+		}
+		return strings.Join(elems, ", "), nil
+	}
+
+	return scalarLiteral(param)
+}
+
+// scalarLiteral renders a single, non-slice value as a SQL literal.
+func scalarLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	case float32, float64:
+		return fmt.Sprintf("%v", v), nil
+	case []byte:
+		return quoteString(string(v)), nil
+	case string:
+		return quoteString(v), nil
+	case time.Time:
+		return quoteString(v.UTC().Format("2006-01-02 15:04:05")), nil
+	default:
+		return "", fmt.Errorf("dbr: Interpolate: unsupported param type %T", value)
+	}
+}
+
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,74 @@
+package dbr
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+// fakeUUID stands in for a package like github.com/gofrs/uuid: a fixed-size
+// byte array that renders itself via driver.Valuer instead of being treated
+// as a []byte blob.
+type fakeUUID [16]byte
+
+func (u fakeUUID) Value() (driver.Value, error) {
+	return fmt.Sprintf("%x", [16]byte(u)), nil
+}
+
+func TestInterpolateSliceExpansion(t *testing.T) {
+	cases := []struct {
+		name   string
+		sql    string
+		params []interface{}
+		want   string
+	}{
+		{
+			name:   "int64 slice",
+			sql:    "select * from foo where id in (?)",
+			params: []interface{}{[]int64{1, 2, 3}},
+			want:   "select * from foo where id in (1, 2, 3)",
+		},
+		{
+			name:   "string slice",
+			sql:    "select * from foo where name in (?)",
+			params: []interface{}{[]string{"a", "b"}},
+			want:   "select * from foo where name in ('a', 'b')",
+		},
+		{
+			name:   "empty slice",
+			sql:    "select * from foo where id in (?)",
+			params: []interface{}{[]int64{}},
+			want:   "select * from foo where id in (NULL)",
+		},
+		{
+			name:   "byte slice is not expanded",
+			sql:    "select * from foo where data = ?",
+			params: []interface{}{[]byte("hello")},
+			want:   "select * from foo where data = 'hello'",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Interpolate(c.sql, c.params)
+			if err != nil {
+				t.Fatalf("Interpolate returned error: %s", err)
+			}
+			if got != c.want {
+				t.Fatalf("Interpolate(%q) = %q, want %q", c.sql, got, c.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateSliceOfValuers(t *testing.T) {
+	ids := []fakeUUID{{1}, {2}}
+	got, err := Interpolate("select * from foo where id in (?)", []interface{}{ids})
+	if err != nil {
+		t.Fatalf("Interpolate returned error: %s", err)
+	}
+	want := "select * from foo where id in ('01000000000000000000000000000000', '02000000000000000000000000000000')"
+	if got != want {
+		t.Fatalf("Interpolate = %q, want %q", got, want)
+	}
+}
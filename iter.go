@@ -0,0 +1,106 @@
+package dbr
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// RowIter is a cursor over a *sql.Rows returned by SelectIter. Unlike
+// SelectAll, it never buffers more than the current row in memory, so it's
+// the right tool for reporting/ETL-sized result sets. Callers must Close it
+// once done; a typical loop looks like:
+//
+//	iter, err := sess.SelectIter(new(Order), "select * from orders")
+//	if err != nil { ... }
+//	defer iter.Close()
+//	for iter.Next() {
+//		var order Order
+//		if err := iter.Scan(&order); err != nil { ... }
+//	}
+//	if err := iter.Err(); err != nil { ... }
+type RowIter struct {
+	sess       *Session
+	rows       *sql.Rows
+	recordType reflect.Type
+	err        error
+}
+
+// SelectIter runs sql and returns a streaming cursor over the results.
+// recordPrototype is only consulted for its type (an addr of the struct
+// Scan will be called with on each row) and is never itself modified.
+func (sess *Session) SelectIter(recordPrototype interface{}, sql string, params ...interface{}) (*RowIter, error) {
+	valueOfPrototype := reflect.ValueOf(recordPrototype)
+	if valueOfPrototype.Kind() != reflect.Ptr || valueOfPrototype.Elem().Kind() != reflect.Struct {
+		panic("SelectIter: recordPrototype must be the address of a struct")
+	}
+
+	fullSql, err := sess.interpolate(sql, params)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	defer func() {
+		sess.TimingKv("dbr.select_iter", time.Since(startTime).Nanoseconds(), map[string]string{"sql": fullSql})
+	}()
+
+	rows, err := sess.cxn.Db.Query(fullSql)
+	if err != nil {
+		sess.EventErrKv("dbr.select_iter.query.error", err, kvs{"sql": fullSql})
+		return nil, err
+	}
+
+	return &RowIter{sess: sess, rows: rows, recordType: valueOfPrototype.Elem().Type()}, nil
+}
+
+// Next advances the cursor to the next row, returning false when the result
+// set is exhausted or an error occurred (check Err to tell the two apart).
+func (iter *RowIter) Next() bool {
+	if iter.err != nil {
+		return false
+	}
+	return iter.rows.Next()
+}
+
+// Scan loads the current row into dest, which must be the address of a
+// struct of the same type passed to SelectIter.
+func (iter *RowIter) Scan(dest interface{}) error {
+	valueOfDest := reflect.ValueOf(dest)
+	if valueOfDest.Kind() != reflect.Ptr || valueOfDest.Elem().Kind() != reflect.Struct {
+		panic("RowIter.Scan: dest must be the address of a struct")
+	}
+	if t := valueOfDest.Elem().Type(); t != iter.recordType {
+		err := fmt.Errorf("dbr: RowIter.Scan: dest is %s, but iterator was created for %s", t, iter.recordType)
+		iter.err = err
+		return err
+	}
+
+	holder, err := iter.sess.holderFor(iter.recordType, valueOfDest.Elem(), iter.rows)
+	if err != nil {
+		iter.err = err
+		return err
+	}
+
+	if err := iter.rows.Scan(holder...); err != nil {
+		iter.err = err
+		return err
+	}
+	return nil
+}
+
+// Err returns the error latched by a failed Scan, if any, and otherwise any
+// error the underlying rows hit while iterating.
+func (iter *RowIter) Err() error {
+	if iter.err != nil {
+		return iter.err
+	}
+	return iter.rows.Err()
+}
+
+// Close releases the underlying *sql.Rows. It's safe to call more than
+// once.
+func (iter *RowIter) Close() error {
+	return iter.rows.Close()
+}
@@ -0,0 +1,87 @@
+package dbr
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+type itUser struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+type itOther struct {
+	ID int `db:"id"`
+}
+
+func TestRowIterLifecycle(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "alice"},
+		{int64(2), "bob"},
+	})
+
+	iter, err := sess.SelectIter(new(itUser), "select id, name from users")
+	if err != nil {
+		t.Fatalf("SelectIter: %s", err)
+	}
+	defer iter.Close()
+
+	var got []itUser
+	for iter.Next() {
+		var u itUser
+		if err := iter.Scan(&u); err != nil {
+			t.Fatalf("Scan: %s", err)
+		}
+		got = append(got, u)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Err: %s", err)
+	}
+	if len(got) != 2 || got[0].Name != "alice" || got[1].Name != "bob" {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+}
+
+func TestRowIterScanTypeMismatchLatchesError(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "alice"},
+		{int64(2), "bob"},
+	})
+
+	iter, err := sess.SelectIter(new(itUser), "select id, name from users")
+	if err != nil {
+		t.Fatalf("SelectIter: %s", err)
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		t.Fatalf("expected a first row")
+	}
+	if err := iter.Scan(&itOther{}); err == nil {
+		t.Fatalf("expected Scan to reject a dest of the wrong type")
+	}
+
+	if iter.Next() {
+		t.Fatalf("expected Next to report false after Scan latched an error, even though more rows remain")
+	}
+	if err := iter.Err(); err == nil {
+		t.Fatalf("expected Err to return the latched Scan error")
+	}
+}
+
+func TestSelectIterEmptyResult(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, nil)
+
+	iter, err := sess.SelectIter(new(itUser), "select id, name from users")
+	if err != nil {
+		t.Fatalf("SelectIter: %s", err)
+	}
+	defer iter.Close()
+
+	if iter.Next() {
+		t.Fatalf("expected no rows")
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Err: %s", err)
+	}
+}
@@ -0,0 +1,143 @@
+package dbr
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// SelectAllNamed is SelectAll, but sql may use ":name" placeholders that are
+// resolved from source instead of positional "?" params. source is either a
+// map[string]interface{} or a struct whose exported fields are matched
+// against placeholder names via their "db" tag, falling back to the field
+// name.
+func (sess *Session) SelectAllNamed(dest interface{}, sql string, source interface{}) (int, error) {
+	resolvedSql, args, err := bindNamed(sql, source)
+	if err != nil {
+		return 0, err
+	}
+	return sess.SelectAll(dest, resolvedSql, args...)
+}
+
+// SelectOneNamed is SelectOne, but sql may use ":name" placeholders; see
+// SelectAllNamed for how source is resolved.
+func (sess *Session) SelectOneNamed(dest interface{}, sql string, source interface{}) (bool, error) {
+	resolvedSql, args, err := bindNamed(sql, source)
+	if err != nil {
+		return false, err
+	}
+	return sess.SelectOne(dest, resolvedSql, args...)
+}
+
+// namedParamRegexp matches ":name" placeholders. Dots are allowed after the
+// first character so a nested struct field's dotted name like
+// "address.city" (see fieldMapFor) can be bound straight from SQL as
+// ":address.city", not just top-level field names.
+var namedParamRegexp = regexp.MustCompile(`:[a-zA-Z_][a-zA-Z0-9_.]*`)
+
+// namedPlan is the parsed form of a query that uses ":name" placeholders:
+// the query rewritten with positional "?" placeholders, and the names to
+// pull args from, in the order they appear.
+type namedPlan struct {
+	sql   string
+	names []string
+}
+
+var namedPlanCache sync.Map // map[string]*namedPlan, keyed by the original sql
+
+// namedPlanFor parses sql's ":name" placeholders, caching the result so a
+// query bound repeatedly (e.g. in a loop) is only parsed once.
+func namedPlanFor(sql string) *namedPlan {
+	if cached, ok := namedPlanCache.Load(sql); ok {
+		return cached.(*namedPlan)
+	}
+
+	var names []string
+	rewritten := namedParamRegexp.ReplaceAllStringFunc(sql, func(match string) string {
+		names = append(names, match[1:])
+		return "?"
+	})
+
+	plan := &namedPlan{sql: rewritten, names: names}
+	actual, _ := namedPlanCache.LoadOrStore(sql, plan)
+	return actual.(*namedPlan)
+}
+
+// bindNamed resolves sql's ":name" placeholders against source and returns
+// the query rewritten with positional "?" placeholders, plus the args to
+// pass alongside it. Interpolate still does the actual value-to-SQL
+// escaping; bindNamed only turns named params into positional ones.
+func bindNamed(sql string, source interface{}) (string, []interface{}, error) {
+	plan := namedPlanFor(sql)
+	if len(plan.names) == 0 {
+		return plan.sql, nil, nil
+	}
+
+	lookup, err := namedValueLookup(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := make([]interface{}, len(plan.names))
+	for i, name := range plan.names {
+		value, ok := lookup(name)
+		if !ok {
+			return "", nil, fmt.Errorf("dbr: bindNamed: no value bound for :%s", name)
+		}
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return "", nil, fmt.Errorf("dbr: bindNamed: :%s: %s", name, err)
+			}
+			value = v
+		}
+		args[i] = value
+	}
+
+	return plan.sql, args, nil
+}
+
+// namedValueLookup returns a function resolving a placeholder name to its
+// value in source, which must be a map[string]interface{} or a struct.
+func namedValueLookup(source interface{}) (func(name string) (interface{}, bool), error) {
+	if m, ok := source.(map[string]interface{}); ok {
+		return func(name string) (interface{}, bool) {
+			v, ok := m[name]
+			return v, ok
+		}, nil
+	}
+
+	val := reflect.ValueOf(source)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dbr: bindNamed: source must be a map[string]interface{} or a struct, got %T", source)
+	}
+
+	fm := fieldMapFor(val.Type())
+	return func(name string) (interface{}, bool) {
+		index, ok := fm[name]
+		if !ok {
+			return nil, false
+		}
+		return fieldByIndexReadOnly(val, index)
+	}, nil
+}
+
+// fieldByIndexReadOnly is FieldByIndex, except a nil pointer anywhere along
+// the path yields ok == false instead of panicking.
+func fieldByIndexReadOnly(val reflect.Value, index []int) (interface{}, bool) {
+	for _, i := range index {
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return nil, false
+			}
+			val = val.Elem()
+		}
+		val = val.Field(i)
+	}
+	return val.Interface(), true
+}
@@ -0,0 +1,70 @@
+package dbr
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type nmHome struct {
+	City string `db:"city"`
+}
+
+type nmUser struct {
+	Name string `db:"name"`
+	Home nmHome `db:"home"`
+}
+
+func TestBindNamedResolvesDottedPathFromStruct(t *testing.T) {
+	sql, args, err := bindNamed("select * from users where name = :name and city = :home.city", nmUser{
+		Name: "alice",
+		Home: nmHome{City: "NYC"},
+	})
+	if err != nil {
+		t.Fatalf("bindNamed: %s", err)
+	}
+	if sql != "select * from users where name = ? and city = ?" {
+		t.Fatalf("unexpected rewritten sql: %q", sql)
+	}
+	if len(args) != 2 || args[0] != "alice" || args[1] != "NYC" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestBindNamedResolvesFromMap(t *testing.T) {
+	sql, args, err := bindNamed("select * from users where name = :name", map[string]interface{}{"name": "bob"})
+	if err != nil {
+		t.Fatalf("bindNamed: %s", err)
+	}
+	if sql != "select * from users where name = ?" {
+		t.Fatalf("unexpected rewritten sql: %q", sql)
+	}
+	if len(args) != 1 || args[0] != "bob" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestBindNamedMissingKeyErrors(t *testing.T) {
+	_, _, err := bindNamed("select * from users where name = :name", map[string]interface{}{})
+	if err == nil {
+		t.Fatalf("expected an error for a placeholder with no bound value")
+	}
+	if !strings.Contains(err.Error(), "no value bound for :name") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestSelectAllNamedResolvesAgainstSource(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "alice"},
+	})
+
+	var dest []*sqUser
+	n, err := sess.SelectAllNamed(&dest, "select id, name from users where name = :name", map[string]interface{}{"name": "alice"})
+	if err != nil {
+		t.Fatalf("SelectAllNamed: %s", err)
+	}
+	if n != 1 || len(dest) != 1 || dest[0].Name != "alice" {
+		t.Fatalf("unexpected result: n=%d dest=%+v", n, dest)
+	}
+}
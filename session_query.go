@@ -12,15 +12,35 @@ import (
 // For fields in the structure that aren't in the query but without db:"-", return error
 // For fields in the query that aren't in the structure, we'll ignore them.
 
+// defaultMapKeyField is the struct field (matched by its "db" tag, or its
+// name if untagged) used to key the destination map when SelectAll is asked
+// to fill a map and no explicit key field was given.
+const defaultMapKeyField = "id"
+
 // dest can be:
 // - addr of a structure
 // - addr of slice of pointers to structures
 // - map of pointers to structures (addr of map also ok)
 // If it's a single structure, only the first record returned will be set.
 // If it's a slice or map, the slice/map won't be emptied first. New records will be allocated for each found record.
-// If its a map, there is the potential to overwrite values (keys are 'id')
+// If it's a map, keys come from each record's "id" field (matched by "db"
+// tag, or field name if untagged) and a later row with a key already seen
+// overwrites the earlier one; use SelectAllMap to key off a different field.
 // Returns the number of items found (which is not necessarily the # of items set)
 func (sess *Session) SelectAll(dest interface{}, sql string, params ...interface{}) (int, error) {
+	return sess.selectAll(dest, defaultMapKeyField, sql, params)
+}
+
+// SelectAllMap behaves like SelectAll, but is for map destinations only: it
+// keys the map with the struct field named by keyField (matched against the
+// field's "db" tag, falling back to the field name) instead of the "id"
+// default. A row whose key has already been seen overwrites the previous
+// entry.
+func (sess *Session) SelectAllMap(dest interface{}, keyField string, sql string, params ...interface{}) (int, error) {
+	return sess.selectAll(dest, keyField, sql, params)
+}
+
+func (sess *Session) selectAll(dest interface{}, keyField string, sql string, params []interface{}) (int, error) {
 
 	//
 	// Validate the dest, and extract the reflection values we need.
@@ -54,7 +74,7 @@ func (sess *Session) SelectAll(dest interface{}, sql string, params ...interface
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(sql, params)
+	fullSql, err := sess.interpolate(sql, params)
 	if err != nil {
 		return 0, err
 	}
@@ -101,7 +121,47 @@ func (sess *Session) SelectAll(dest interface{}, sql string, params ...interface
 		}
 		valueOfDest.Set(sliceValue)
 	} else { // Map
+		mapValue := valueOfDest
+		if mapValue.IsNil() {
+			if !mapValue.CanSet() {
+				return numberOfRowsReturned, fmt.Errorf("dbr: SelectAll: destination map is nil; pass the address of a map instead")
+			}
+			mapValue.Set(reflect.MakeMap(mapValue.Type()))
+		}
+
+		keyType := mapValue.Type().Key()
+		// Use the same fieldMap holderFor scans with, so a key field
+		// promoted from an embedded struct (e.g. an "id" on a Base) is
+		// found exactly like any other column would be.
+		fieldIndex, ok := fieldMapFor(recordType)[keyField]
+		if !ok {
+			return numberOfRowsReturned, fmt.Errorf("dbr: SelectAll: key field %q not found on %s", keyField, recordType.Name())
+		}
+		if fieldType := recordType.FieldByIndex(fieldIndex).Type; fieldType != keyType {
+			return numberOfRowsReturned, fmt.Errorf("dbr: SelectAll: key field %q is %s, but map key is %s", keyField, fieldType, keyType)
+		}
+
+		for rows.Next() {
+			// Create a new record to store our row:
+			pointerToNewRecord := reflect.New(recordType)
+			newRecord := reflect.Indirect(pointerToNewRecord)
+
+			holder, err := sess.holderFor(recordType, newRecord, rows)
+			if err != nil {
+				return numberOfRowsReturned, err
+			}
+
+			err = rows.Scan(holder...)
+			if err != nil {
+				return numberOfRowsReturned, err
+			}
 
+			// Rows are keyed off of the record's key field. A later row with
+			// a key already seen overwrites the earlier one.
+			mapValue.SetMapIndex(fieldByIndexAlloc(newRecord, fieldIndex), pointerToNewRecord)
+
+			numberOfRowsReturned += 1
+		}
 	}
 
 	// Check for errors at the end. Supposedly these are error that can happen during iteration.
@@ -129,7 +189,7 @@ func (sess *Session) SelectOne(dest interface{}, sql string, params ...interface
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(sql, params)
+	fullSql, err := sess.interpolate(sql, params)
 	if err != nil {
 		return false, err
 	}
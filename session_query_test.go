@@ -0,0 +1,64 @@
+package dbr
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type sqUser struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestSelectAllMapLaterRowOverwritesEarlierOnDuplicateKey(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "first"},
+		{int64(1), "second"},
+	})
+
+	dest := make(map[int]*sqUser)
+	n, err := sess.SelectAllMap(dest, "id", "select id, name from users")
+	if err != nil {
+		t.Fatalf("SelectAllMap: %s", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows returned, got %d", n)
+	}
+	if len(dest) != 1 {
+		t.Fatalf("expected 1 map entry after duplicate key, got %d", len(dest))
+	}
+	if dest[1].Name != "second" {
+		t.Fatalf("expected the later row to win, got %q", dest[1].Name)
+	}
+}
+
+func TestSelectAllMapKeyTypeMismatch(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "first"},
+	})
+
+	dest := make(map[string]*sqUser)
+	_, err := sess.SelectAllMap(dest, "id", "select id, name from users")
+	if err == nil {
+		t.Fatalf("expected an error for a map keyed by string against an int field")
+	}
+	if !strings.Contains(err.Error(), "map key is string") {
+		t.Fatalf("expected error to mention the key type mismatch, got %q", err)
+	}
+}
+
+func TestSelectAllMapNilMapWithoutAddrErrors(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id", "name"}, [][]driver.Value{
+		{int64(1), "first"},
+	})
+
+	var dest map[int]*sqUser
+	_, err := sess.SelectAllMap(dest, "id", "select id, name from users")
+	if err == nil {
+		t.Fatalf("expected an error for a nil map passed without its address")
+	}
+	if !strings.Contains(err.Error(), "pass the address of a map instead") {
+		t.Fatalf("expected the nil-map error, got %q", err)
+	}
+}
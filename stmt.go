@@ -0,0 +1,314 @@
+package dbr
+
+import (
+	"container/list"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Stmt is a prepared statement bound to a Session, returned by
+// Session.Prepare or Session.PrepareTx. It exposes the same SelectAll /
+// SelectOne / Exec surface as Session, but runs params through the
+// driver's own placeholder binding instead of Interpolate, so the SQL text
+// handed to the driver never changes between calls and can actually be
+// prepared/cached server-side.
+type Stmt struct {
+	sess *Session
+	sql  string
+	stmt *sql.Stmt
+
+	mu       sync.Mutex
+	inUse    int
+	doClosed bool
+}
+
+// Prepare prepares sql (written with "?" placeholders, same as the
+// database/sql driver expects) against the session's connection and
+// returns a reusable handle for it. Note this bypasses Interpolate
+// entirely: params are bound by the driver, not inlined into the SQL text.
+func (sess *Session) Prepare(sql string) (*Stmt, error) {
+	stmt, err := sess.cxn.Db.Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{sess: sess, sql: sql, stmt: stmt}, nil
+}
+
+// PrepareTx is Prepare, but prepares sql against tx instead of the
+// session's pooled connection: the returned Stmt only runs within tx, and
+// database/sql closes its underlying *sql.Stmt automatically once tx
+// commits or rolls back, so it doesn't outlive the transaction it was
+// prepared on. Calling Close on it afterwards is a harmless no-op that
+// returns nil.
+func (sess *Session) PrepareTx(tx *sql.Tx, sql string) (*Stmt, error) {
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{sess: sess, sql: sql, stmt: stmt}, nil
+}
+
+// acquire marks the statement as in use for the duration of one call,
+// refusing new callers once Close has been requested. It pairs with
+// release, which is what actually closes the underlying *sql.Stmt once the
+// last in-flight caller is done -- this is what lets stmtCache evict (i.e.
+// Close) a Stmt a concurrent caller is still mid-Query/Exec on without
+// that caller seeing "sql: statement is closed".
+func (s *Stmt) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.doClosed {
+		return errors.New("dbr: Stmt: already closed")
+	}
+	s.inUse++
+	return nil
+}
+
+func (s *Stmt) release() {
+	s.mu.Lock()
+	s.inUse--
+	closeNow := s.inUse == 0 && s.doClosed
+	s.mu.Unlock()
+	if closeNow {
+		s.stmt.Close()
+	}
+}
+
+// Close requests that the underlying *sql.Stmt be released. If the
+// statement is currently in use -- another goroutine is mid-Query/Exec
+// through Exec, SelectAll or SelectOne -- the actual close is deferred
+// until the last such call finishes, rather than closing out from under
+// it. Holding a Stmt between calls (e.g. one returned by PreparePooled)
+// does not count as in use.
+func (s *Stmt) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doClosed = true
+	if s.inUse == 0 {
+		return s.stmt.Close()
+	}
+	return nil
+}
+
+// Exec runs the statement for its side effects.
+func (s *Stmt) Exec(params ...interface{}) (sql.Result, error) {
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	startTime := time.Now()
+	defer func() {
+		s.sess.TimingKv("dbr.stmt.exec", time.Since(startTime).Nanoseconds(), map[string]string{"sql": s.sql})
+	}()
+	return s.stmt.Exec(params...)
+}
+
+// SelectAll runs the prepared statement and scans every row into dest, an
+// addr of a slice of struct pointers. See Session.SelectAll for the
+// allocation semantics; Stmt only supports slice destinations, not maps.
+func (s *Stmt) SelectAll(dest interface{}, params ...interface{}) (int, error) {
+	valueOfDest := reflect.ValueOf(dest)
+	if valueOfDest.Kind() != reflect.Ptr {
+		panic("invalid type passed to Stmt.SelectAll. Need addr of slice")
+	}
+	valueOfDest = reflect.Indirect(valueOfDest)
+	if valueOfDest.Kind() != reflect.Slice {
+		panic("invalid type passed to Stmt.SelectAll. Need addr of slice")
+	}
+
+	recordType := valueOfDest.Type().Elem()
+	if recordType.Kind() != reflect.Ptr || recordType.Elem().Kind() != reflect.Struct {
+		panic("Elements need to be pointers to structures")
+	}
+	recordType = recordType.Elem()
+
+	if err := s.acquire(); err != nil {
+		return 0, err
+	}
+	defer s.release()
+
+	startTime := time.Now()
+	defer func() {
+		s.sess.TimingKv("dbr.stmt.select", time.Since(startTime).Nanoseconds(), map[string]string{"sql": s.sql})
+	}()
+
+	rows, err := s.stmt.Query(params...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	numberOfRowsReturned := 0
+	sliceValue := valueOfDest
+	for rows.Next() {
+		pointerToNewRecord := reflect.New(recordType)
+		newRecord := reflect.Indirect(pointerToNewRecord)
+
+		holder, err := s.sess.holderFor(recordType, newRecord, rows)
+		if err != nil {
+			return numberOfRowsReturned, err
+		}
+		if err = rows.Scan(holder...); err != nil {
+			return numberOfRowsReturned, err
+		}
+
+		sliceValue = reflect.Append(sliceValue, pointerToNewRecord)
+		numberOfRowsReturned += 1
+	}
+	valueOfDest.Set(sliceValue)
+
+	if err = rows.Err(); err != nil {
+		return numberOfRowsReturned, err
+	}
+	return numberOfRowsReturned, nil
+}
+
+// SelectOne runs the prepared statement and scans the first row into dest,
+// an addr of a struct.
+func (s *Stmt) SelectOne(dest interface{}, params ...interface{}) (bool, error) {
+	valueOfDest := reflect.ValueOf(dest)
+	indirectOfDest := reflect.Indirect(valueOfDest)
+	if valueOfDest.Kind() != reflect.Ptr || indirectOfDest.Kind() != reflect.Struct {
+		panic("you need to pass in the address of a struct")
+	}
+	recordType := indirectOfDest.Type()
+
+	if err := s.acquire(); err != nil {
+		return false, err
+	}
+	defer s.release()
+
+	startTime := time.Now()
+	defer func() {
+		s.sess.TimingKv("dbr.stmt.select", time.Since(startTime).Nanoseconds(), map[string]string{"sql": s.sql})
+	}()
+
+	rows, err := s.stmt.Query(params...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		holder, err := s.sess.holderFor(recordType, indirectOfDest, rows)
+		if err != nil {
+			return false, err
+		}
+		if err = rows.Scan(holder...); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// stmtCache is an LRU-bounded pool of prepared statements for one
+// connection, keyed by SQL text. It's deliberately not size-unbounded:
+// SQL built by expanding a slice param into "IN (?, ?, ...)" has one
+// distinct shape per arity, and without a bound that would grow the cache
+// without end.
+type stmtCache struct {
+	sess  *Session
+	size  int
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type stmtCacheEntry struct {
+	sql  string
+	stmt *Stmt
+}
+
+var stmtCaches sync.Map // map[*sql.DB]*stmtCache
+
+// EnableStmtCache turns on a per-connection LRU cache of prepared
+// statements, bounded to size entries. Once enabled, PreparePooled serves
+// (and populates) it instead of preparing a fresh *sql.Stmt every time.
+// size must be at least 1, since a cache that can hold zero entries would
+// have nowhere to put the very statement it just prepared.
+func (sess *Session) EnableStmtCache(size int) {
+	if size < 1 {
+		panic("dbr: EnableStmtCache: size must be at least 1")
+	}
+	stmtCaches.Store(sess.cxn.Db, &stmtCache{
+		sess:  sess,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	})
+}
+
+// PreparePooled is Prepare, but shares a single *Stmt per distinct sql
+// across callers via the cache enabled by EnableStmtCache. It panics if
+// the session's connection hasn't had EnableStmtCache called on it, same
+// as other dbr options that must be turned on before use.
+func (sess *Session) PreparePooled(sql string) (*Stmt, error) {
+	cached, ok := stmtCaches.Load(sess.cxn.Db)
+	if !ok {
+		panic("dbr: PreparePooled: call EnableStmtCache first")
+	}
+	return cached.(*stmtCache).get(sql)
+}
+
+// get serves sql from the cache, preparing (and, if the cache is full,
+// evicting) on a miss. The actual DB round-trips -- Prepare on a miss,
+// Close on an eviction -- run with c.mu released, so a caller asking for
+// a different, already-cached SQL doesn't have to wait on them.
+func (c *stmtCache) get(sql string) (*Stmt, error) {
+	c.mu.Lock()
+	if el, ok := c.items[sql]; ok {
+		c.ll.MoveToFront(el)
+		stmt := el.Value.(*stmtCacheEntry).stmt
+		c.mu.Unlock()
+		return stmt, nil
+	}
+	c.mu.Unlock()
+
+	stmt, err := c.sess.Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[sql]; ok {
+		// Someone else populated this entry while we were preparing our
+		// own copy. Keep theirs -- other callers may already be holding
+		// it -- and let ours go.
+		c.ll.MoveToFront(el)
+		existing := el.Value.(*stmtCacheEntry).stmt
+		c.mu.Unlock()
+		stmt.Close()
+		return existing, nil
+	}
+
+	el := c.ll.PushFront(&stmtCacheEntry{sql: sql, stmt: stmt})
+	c.items[sql] = el
+
+	var evicted *Stmt
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		entry := oldest.Value.(*stmtCacheEntry)
+		c.ll.Remove(oldest)
+		delete(c.items, entry.sql)
+		evicted = entry.stmt
+	}
+	c.mu.Unlock()
+
+	if evicted != nil {
+		// Close only requests closing: if another goroutine is still
+		// mid-Query/Exec on this evicted Stmt, the *sql.Stmt isn't
+		// actually closed until that call releases it.
+		evicted.Close()
+	}
+
+	return stmt, nil
+}
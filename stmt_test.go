@@ -0,0 +1,91 @@
+package dbr
+
+import "testing"
+
+func TestStmtCloseDefersWhileInUse(t *testing.T) {
+	sess, d := newFakeSession(t, []string{"id"}, nil)
+
+	stmt, err := sess.Prepare("select id from t")
+	if err != nil {
+		t.Fatalf("Prepare: %s", err)
+	}
+
+	if err := stmt.acquire(); err != nil {
+		t.Fatalf("acquire: %s", err)
+	}
+
+	if err := stmt.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	if d.closeCount != 0 {
+		t.Fatalf("expected the underlying stmt to stay open while a caller still holds it, closeCount=%d", d.closeCount)
+	}
+
+	if err := stmt.acquire(); err == nil {
+		t.Fatalf("expected acquire to refuse new callers once Close has been requested")
+	}
+
+	stmt.release()
+	if d.closeCount != 1 {
+		t.Fatalf("expected the underlying stmt closed once the last caller released it, closeCount=%d", d.closeCount)
+	}
+}
+
+func TestEnableStmtCachePanicsOnSizeLessThanOne(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id"}, nil)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("expected EnableStmtCache(0) to panic")
+		}
+	}()
+	sess.EnableStmtCache(0)
+}
+
+func TestStmtCacheHitReturnsSameInstance(t *testing.T) {
+	sess, _ := newFakeSession(t, []string{"id"}, nil)
+	sess.EnableStmtCache(2)
+
+	first, err := sess.PreparePooled("select 1")
+	if err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	second, err := sess.PreparePooled("select 1")
+	if err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	if first != second {
+		t.Fatalf("expected a cache hit to return the same *Stmt instance")
+	}
+}
+
+func TestStmtCacheEvictsOldestAndClosesIt(t *testing.T) {
+	sess, d := newFakeSession(t, []string{"id"}, nil)
+	sess.EnableStmtCache(2)
+
+	if _, err := sess.PreparePooled("select 1"); err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	if _, err := sess.PreparePooled("select 2"); err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	if d.closeCount != 0 {
+		t.Fatalf("expected no eviction yet, closeCount=%d", d.closeCount)
+	}
+
+	third, err := sess.PreparePooled("select 3")
+	if err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	if d.closeCount != 1 {
+		t.Fatalf("expected the oldest entry (select 1) to be evicted and closed, closeCount=%d", d.closeCount)
+	}
+
+	again, err := sess.PreparePooled("select 3")
+	if err != nil {
+		t.Fatalf("PreparePooled: %s", err)
+	}
+	if again != third {
+		t.Fatalf("expected the just-inserted entry to still be cached")
+	}
+}